@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// cacheDBFile is the on-disk SQLite cache database used for incremental
+// extraction, holding per-object rows (see Cache.FilterChanged and the
+// -changed-only/-since flags in main.go). It is separate from the data.json
+// snapshot that -cached and migrations-diff read.
+const cacheDBFile = "cache.db"
+
+// Cache is a persistent, per-object extraction cache backed by SQLite (the
+// same mattn/go-sqlite3 driver already used for "dbtype: sqlite" sources). It
+// stores one row per (database, schema, name) with a content checksum and a
+// last_seen timestamp, so CI runs can tell which objects actually changed
+// since the previous extraction.
+type Cache struct {
+	db *sql.DB
+}
+
+// cacheEntry is one row of the extraction_cache table.
+type cacheEntry struct {
+	Checksum string
+	LastSeen time.Time
+}
+
+// openCache opens (creating if necessary) the SQLite cache database at path.
+func openCache(path string) (*Cache, error) {
+	// MarkSeen is called concurrently by queryDatabasesStream's worker pool,
+	// so without a busy timeout a second writer hitting the cache while
+	// another upsert is in flight fails immediately with "database is locked"
+	// instead of waiting for it to finish.
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	const schema = `CREATE TABLE IF NOT EXISTS extraction_cache (
+		database  TEXT NOT NULL,
+		schema    TEXT NOT NULL,
+		name      TEXT NOT NULL,
+		checksum  TEXT NOT NULL,
+		last_seen DATETIME NOT NULL,
+		PRIMARY KEY (database, schema, name)
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Cache{db: db}, nil
+}
+
+// Close releases the underlying database handle.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// checksum hashes a table/view's definition together with its column
+// signature, so that a change to either is detected.
+func checksum(info TableInfo) string {
+	var sig strings.Builder
+	sig.WriteString(info.Definition)
+	for _, col := range info.Columns {
+		fmt.Fprintf(&sig, "|%s:%s:%d:%d:%d:%t:%t", col.Name, col.Type_Name, col.Max_Length, col.Precision, col.Scale, col.Is_Nullable, col.Is_Identity)
+	}
+	sum := sha256.Sum256([]byte(sig.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup returns the cached entry for the given object, if any.
+func (c *Cache) lookup(database, schema, name string) (cacheEntry, bool, error) {
+	var entry cacheEntry
+	row := c.db.QueryRow(`SELECT checksum, last_seen FROM extraction_cache WHERE database = ? AND schema = ? AND name = ?`, database, schema, name)
+	if err := row.Scan(&entry.Checksum, &entry.LastSeen); err != nil {
+		if err == sql.ErrNoRows {
+			return cacheEntry{}, false, nil
+		}
+		return cacheEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// upsert records the object's current checksum and last_seen timestamp.
+func (c *Cache) upsert(database, schema, name, sum string, seenAt time.Time) error {
+	_, err := c.db.Exec(`INSERT INTO extraction_cache (database, schema, name, checksum, last_seen) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(database, schema, name) DO UPDATE SET checksum = excluded.checksum, last_seen = excluded.last_seen`,
+		database, schema, name, sum, seenAt)
+	return err
+}
+
+// MarkSeen is the single-object counterpart to FilterChanged, used by
+// streaming callers (queryDatabasesStream consumers) that handle one
+// TableInfo at a time instead of a collected batch. It reports whether info
+// changed since the last time it was seen and records its current state.
+func (c *Cache) MarkSeen(info TableInfo, since time.Time) (bool, error) {
+	sum := checksum(info)
+	prev, found, err := c.lookup(info.Database, info.Schema, info.TableName)
+	if err != nil {
+		return false, err
+	}
+
+	changed := !found || prev.Checksum != sum
+	if !changed && !since.IsZero() && prev.LastSeen.Before(since) {
+		changed = true
+	}
+
+	if err := c.upsert(info.Database, info.Schema, info.TableName, sum, time.Now()); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// FilterChanged compares data against the cache and returns only the
+// objects whose checksum differs from what was last recorded (or that have
+// no prior record at all). It updates the cache with the current state of
+// every object as it goes, so the next run has a fresh baseline. When since
+// is non-zero, an otherwise-unchanged object is still reported as changed if
+// it was last seen before since.
+func (c *Cache) FilterChanged(data []TableInfo, since time.Time) ([]TableInfo, error) {
+	var changed []TableInfo
+
+	for _, info := range data {
+		isChanged, err := c.MarkSeen(info, since)
+		if err != nil {
+			return nil, err
+		}
+		if isChanged {
+			changed = append(changed, info)
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChecksumChangesWithColumnsAndDefinition(t *testing.T) {
+	base := TableInfo{TableName: "t", Definition: "CREATE TABLE t (id int)", Columns: []Column{{Name: "id", Type_Name: "int"}}}
+	same := base
+	same.Columns = append([]Column(nil), base.Columns...)
+
+	if checksum(base) != checksum(same) {
+		t.Fatal("expected identical TableInfo to produce the same checksum")
+	}
+
+	changedDef := base
+	changedDef.Definition = "CREATE TABLE t (id int, name text)"
+	if checksum(base) == checksum(changedDef) {
+		t.Fatal("expected a definition change to change the checksum")
+	}
+
+	changedCols := base
+	changedCols.Columns = append([]Column(nil), Column{Name: "id", Type_Name: "int"}, Column{Name: "email", Type_Name: "text"})
+	if checksum(base) == checksum(changedCols) {
+		t.Fatal("expected a column change to change the checksum")
+	}
+}
+
+func TestMarkSeenReportsChangedOnlyOnce(t *testing.T) {
+	cache, err := openCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	info := TableInfo{Database: "db", TableName: "t", Definition: "CREATE TABLE t (id int)"}
+
+	changed, err := cache.MarkSeen(info, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected the first sighting of an object to report changed")
+	}
+
+	changed, err = cache.MarkSeen(info, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Fatal("expected an unchanged object to report unchanged on the second sighting")
+	}
+
+	info.Definition = "CREATE TABLE t (id int, name text)"
+	changed, err = cache.MarkSeen(info, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected a definition change to report changed")
+	}
+}
+
+// TestMarkSeenConcurrent exercises the scenario queryDatabasesStream's worker
+// pool puts the cache under: many goroutines racing MarkSeen on the same
+// cache.db. Without a busy timeout on the connection, a writer colliding with
+// another in-flight upsert fails immediately with "database is locked"
+// instead of waiting for it.
+func TestMarkSeenConcurrent(t *testing.T) {
+	cache, err := openCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info := TableInfo{Database: "db", TableName: "t", Definition: "CREATE TABLE t (id int)", Schema: string(rune('a' + i))}
+			if _, err := cache.MarkSeen(info, time.Time{}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent MarkSeen failed: %v", err)
+	}
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"log"
 	"sync"
 
+	"golang.org/x/sync/errgroup"
+
 	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// defaultConcurrency is used when Config.Concurrency is unset.
+const defaultConcurrency = 4
+
 // TableInfo represents a table or view
 type TableInfo struct {
 	Database     string
@@ -43,21 +50,6 @@ type Column struct {
 // Database represents a map with the database name as key and a list of tables/views as value
 type Database map[string][]TableInfo
 
-// sqlQueries contains the SQL queries used to query the database
-var sqlQueries = map[string]string{
-	"queryTables":            "SELECT TABLE_CATALOG, TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM [%s].INFORMATION_SCHEMA.TABLES UNION ALL SELECT ROUTINE_CATALOG, ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE FROM [%s].INFORMATION_SCHEMA.ROUTINES",
-	"queryColumns":           "USE %s; SELECT c.Name, [Type_Name] = tp.name, c.Max_Length, c.[Precision], c.Scale, ISNULL(c.Collation_Name, '') as Collation_Name, c.Is_Nullable, c.Is_Identity FROM sys.columns c WITH(NOLOCK) JOIN sys.types tp WITH(NOLOCK) ON c.user_type_id = tp.user_type_id WHERE c.[object_id] = OBJECT_ID(N'[%s].[%s].[%s]')",
-	"queryViewDefinition":    "USE %s; SELECT ISNULL(OBJECT_DEFINITION(OBJECT_ID(N'[%s].[%s].[%s]')),'') as [definition]",
-	"queryTableDependencies": "SELECT ISNULL(referenced_database_name, '') as referenced_database_name, ISNULL(referenced_schema_name,'') as referenced_schema_name, ISNULL(referenced_entity_name,'') as referenced_entity_name FROM [%s].sys.sql_expression_dependencies WHERE referencing_id = OBJECT_ID(N'[%s].[%s].[%s]')",
-}
-
-var sqliteQueries = map[string]string{
-	"queryTables":            "SELECT name, type FROM sqlite_master WHERE type IN ('table', 'view')",
-	"queryColumns":           "PRAGMA table_info(%s)",
-	"queryViewDefinition":    "SELECT sql FROM sqlite_master WHERE name='%s' AND type='view'",
-	"queryTableDependencies": "SELECT '' as referenced_database_name, '' as referenced_schema_name, '' as referenced_entity_name", // SQLite doesn't support this
-}
-
 // typeMap maps the type names from the database to the type names used in the information file
 var typeMap = map[string]string{
 	"BASE TABLE": "Table",
@@ -66,127 +58,177 @@ var typeMap = map[string]string{
 	"PROCEDURE":  "Procedure",
 }
 
-// queryDatabases queries the given databases and returns a list of TableInfo
+// queryDatabases queries the given databases and returns a list of
+// TableInfo. It's a convenience wrapper around queryDatabasesStream for
+// callers that need every object at once (JSON/XML output, migrations, the
+// dependency graph — anything that has to see the whole batch to order or
+// diff it). Callers that can process objects as they arrive, like
+// streamToFiles, should call queryDatabasesStream directly instead.
 func queryDatabases(config Config) ([]TableInfo, error) {
-	var wg sync.WaitGroup
-	results := make(chan TableInfo)
-	errors := make(chan error)
-	done := make(chan bool)
+	var mu sync.Mutex
+	var tableInfos []TableInfo
+
+	err := queryDatabasesStream(config, func(info TableInfo) error {
+		mu.Lock()
+		tableInfos = append(tableInfos, info)
+		mu.Unlock()
+		return nil
+	})
+	return tableInfos, err
+}
+
+// tableRef identifies one table/view/routine discovered in stage 1, plus the
+// already-open connection and dialect stage 2 needs to fetch its details.
+type tableRef struct {
+	db       *sql.DB
+	dialect  Dialect
+	database string
+	schema   string
+	name     string
+	typen    string
+}
+
+// queryDatabasesStream runs a two-stage extraction pipeline across all
+// configured databases and invokes handler once per discovered table/view/
+// routine, in whatever order results become available. Stage 1 lists the
+// tables of each database into a shared channel; stage 2 is a bounded
+// worker pool (sized by config.Concurrency) that fetches each object's
+// definition, columns and dependencies concurrently, sharing one *sql.DB per
+// database via db.SetMaxOpenConns. Both stages are Go funcs in the same
+// errgroup.WithContext, so an error from either side cancels the shared
+// context and unblocks the other — a producer stuck sending on refs and a
+// worker stuck reading from it are released by the same ctx.Done(), instead
+// of leaking goroutines blocked on the unbuffered channel the old
+// implementation used. handler is called concurrently from up to
+// config.Concurrency goroutines and must be safe for that.
+func queryDatabasesStream(config Config, handler func(TableInfo) error) error {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	group, ctx := errgroup.WithContext(context.Background())
+	refs := make(chan tableRef)
+
+	var dbsMu sync.Mutex
+	var dbs []*sql.DB
 
+	var producers sync.WaitGroup
 	for _, database := range config.Databases {
-		wg.Add(1)
-		go func(dbName string) {
-			defer wg.Done()
-			tables, err := queryTables(config.Server, dbName, config)
+		database := database
+		producers.Add(1)
+		group.Go(func() error {
+			defer producers.Done()
+
+			dialect := dialectFor(config.DBType)
+			db, err := sql.Open(dialect.Driver(), dialect.DSN(config.Server, config.User, config.Password, database))
+			if err != nil {
+				return err
+			}
+			// +1 beyond the worker pool size: this connection pool is shared
+			// between stage 1's own open table-listing cursor and stage 2's
+			// per-object detail queries, so sizing it to exactly concurrency
+			// can starve every worker of a connection while the listing
+			// cursor is still open, deadlocking the pipeline even though
+			// stage 1/2 cancellation is otherwise unified.
+			db.SetMaxOpenConns(concurrency + 1)
+
+			dbsMu.Lock()
+			dbs = append(dbs, db)
+			dbsMu.Unlock()
+
+			rows, err := db.Query(dialect.QueryTables(database))
 			if err != nil {
-				errors <- err
-				return
+				return err
 			}
-			for _, table := range tables {
-				results <- table
+			defer rows.Close()
+
+			for rows.Next() {
+				schema, tableName, typen, err := dialect.ScanTableRef(rows)
+				if err != nil {
+					return err
+				}
+
+				select {
+				case refs <- tableRef{db: db, dialect: dialect, database: database, schema: schema, name: tableName, typen: typen}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
-		}(database)
+			return rows.Err()
+		})
 	}
 
 	go func() {
-		wg.Wait()
-		close(results)
-		close(errors)
-		done <- true
+		producers.Wait()
+		close(refs)
 	}()
 
-	var tableInfos []TableInfo
-	var errs []error
-
-	for {
-		select {
-		case table := <-results:
-			tableInfos = append(tableInfos, table)
-		case err := <-errors:
-			errs = append(errs, err)
-		case <-done:
-			if len(errs) > 0 {
-				return tableInfos, errs[0]
+	for i := 0; i < concurrency; i++ {
+		group.Go(func() error {
+			for {
+				select {
+				case ref, ok := <-refs:
+					if !ok {
+						return nil
+					}
+					info, err := fetchTableInfo(ref, config)
+					if err != nil {
+						return err
+					}
+					if err := handler(info); err != nil {
+						return err
+					}
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
-			return tableInfos, nil
-		}
+		})
 	}
-}
 
-// queryTables queries the tables of the given database and returns a list of TableInfo
-func queryTables(server, database string, config Config) ([]TableInfo, error) {
-	var db *sql.DB
-	var err error
-	var query string
-
-	if config.DBType == "sqlite" {
-		db, err = sql.Open("sqlite3", server)
-		query = sqliteQueries["queryTables"]
-	} else {
-		connString := fmt.Sprintf("server=%s;user id=%s;password=%s;port=1433", server, config.User, config.Password)
-		db, err = sql.Open("mssql", connString)
-		query = fmt.Sprintf(sqlQueries["queryTables"], database, database)
+	err := group.Wait()
+
+	for _, db := range dbs {
+		db.Close()
 	}
 
+	return err
+}
+
+// fetchTableInfo fetches the definition, columns and dependencies of a
+// single table/view/routine identified by ref.
+func fetchTableInfo(ref tableRef, config Config) (TableInfo, error) {
+	log.Printf("Database %s, %s: %s \n", ref.database, typeMap[ref.typen], ref.name)
+
+	definition, err := queryViewDefinition(ref.db, ref.dialect, ref.database, ref.schema, ref.name, config)
 	if err != nil {
-		return nil, err
+		return TableInfo{}, err
 	}
-	defer db.Close()
 
-	rows, err := db.Query(query)
+	columns, err := queryTableDefinition(ref.db, ref.dialect, ref.database, ref.schema, ref.name, config)
 	if err != nil {
-		return nil, err
+		return TableInfo{}, err
 	}
-	defer rows.Close()
-
-	var tables []TableInfo
-
-	for rows.Next() {
-		var tableName, typen string
-
-		if err := rows.Scan(&tableName, &typen); err != nil {
-			return nil, err
-		}
-
-		log.Printf("Database %s, %s: %s \n", database, typeMap[typen], tableName)
-
-		definition, err := queryViewDefinition(db, database, tableName, config)
-		if err != nil {
-			return nil, err
-		}
-
-		tablestruct, err := queryTableDefinition(db, database, tableName, config)
-		if err != nil {
-			return nil, err
-		}
-
-		dependencies, err := queryTableDependencies(db, database, tableName, config)
-		if err != nil {
-			return nil, err
-		}
 
-		tables = append(tables, TableInfo{
-			Database:     database,
-			TableName:    tableName,
-			Definition:   definition,
-			Columns:      tablestruct,
-			Dependencies: dependencies,
-			Type:         typen,
-		})
+	dependencies, err := queryTableDependencies(ref.db, ref.dialect, ref.database, ref.schema, ref.name, config)
+	if err != nil {
+		return TableInfo{}, err
 	}
 
-	return tables, nil
+	return TableInfo{
+		Database:     ref.database,
+		Schema:       ref.schema,
+		TableName:    ref.name,
+		Definition:   definition,
+		Columns:      columns,
+		Dependencies: dependencies,
+		Type:         ref.typen,
+	}, nil
 }
 
 // queryTableDefinition queries the table definition of the given table and returns a list of Column
-func queryTableDefinition(db *sql.DB, database, tableName string, config Config) ([]Column, error) {
-	var query string
-
-	if config.DBType == "sqlite" {
-		query = fmt.Sprintf(sqliteQueries["queryColumns"], tableName)
-	} else {
-		query = fmt.Sprintf(sqlQueries["queryColumns"], database, database, "", tableName)
-	}
+func queryTableDefinition(db *sql.DB, dialect Dialect, database, schema, tableName string, config Config) ([]Column, error) {
+	query := dialect.QueryColumns(database, schema, tableName)
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -194,34 +236,12 @@ func queryTableDefinition(db *sql.DB, database, tableName string, config Config)
 	}
 	defer rows.Close()
 
-	var columns []Column
-	for rows.Next() {
-		var col Column
-		if config.DBType == "sqlite" {
-			var cid, notnull, dfltValue, pk int
-			if err := rows.Scan(&cid, &col.Name, &col.Type_Name, &notnull, &dfltValue, &pk); err != nil {
-				return nil, err
-			}
-			col.Is_Nullable = notnull == 0
-		} else {
-			if err := rows.Scan(&col.Name, &col.Type_Name, &col.Max_Length, &col.Precision, &col.Scale, &col.Collation_Name, &col.Is_Nullable, &col.Is_Identity); err != nil {
-				return nil, err
-			}
-		}
-		columns = append(columns, col)
-	}
-	return columns, nil
+	return dialect.ScanColumns(rows)
 }
 
 // queryViewDefinition queries the view definition of the given view and returns the definition as string
-func queryViewDefinition(db *sql.DB, database, tableName string, config Config) (string, error) {
-	var query string
-
-	if config.DBType == "sqlite" {
-		query = fmt.Sprintf(sqliteQueries["queryViewDefinition"], tableName)
-	} else {
-		query = fmt.Sprintf(sqlQueries["queryViewDefinition"], database, database, "", tableName)
-	}
+func queryViewDefinition(db *sql.DB, dialect Dialect, database, schema, tableName string, config Config) (string, error) {
+	query := dialect.QueryViewDefinition(database, schema, tableName)
 
 	row := db.QueryRow(query)
 
@@ -234,14 +254,8 @@ func queryViewDefinition(db *sql.DB, database, tableName string, config Config)
 }
 
 // queryTableDependencies queries the dependencies of the given table and returns a list of Dependency
-func queryTableDependencies(db *sql.DB, database, tableName string, config Config) ([]Dependency, error) {
-	var query string
-
-	if config.DBType == "sqlite" {
-		query = sqliteQueries["queryTableDependencies"]
-	} else {
-		query = fmt.Sprintf(sqlQueries["queryTableDependencies"], database, database, "", tableName)
-	}
+func queryTableDependencies(db *sql.DB, dialect Dialect, database, schema, tableName string, config Config) ([]Dependency, error) {
+	query := dialect.QueryDependencies(database, schema, tableName)
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestQueryDatabasesStreamCancelsOnWorkerError guards against the pipeline
+// deadlocking when a worker (stage 2) errors out while a producer (stage 1)
+// is still listing tables: both stages must share one cancellation context,
+// and the shared *sql.DB connection pool must have headroom for stage 1's
+// own open listing cursor alongside stage 2's detail queries.
+func TestQueryDatabasesStreamCancelsOnWorkerError(t *testing.T) {
+	path := newSQLiteFixture(t, 3)
+
+	config := Config{
+		Server:      path,
+		DBType:      "sqlite",
+		Databases:   []string{"db1", "db2"},
+		Concurrency: 1,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- queryDatabasesStream(config, func(TableInfo) error {
+			return errors.New("boom")
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected the worker error to propagate")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queryDatabasesStream did not return after a worker error")
+	}
+}
+
+// newSQLiteFixture creates a temporary SQLite database with n empty tables
+// and returns its path, registering cleanup with t.
+func newSQLiteFixture(t *testing.T, n int) string {
+	t.Helper()
+
+	f, err := os.CreateTemp("", "dbextractor-fixture-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := f.Name()
+	f.Close()
+	t.Cleanup(func() { os.Remove(path) })
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err := db.Exec(fmt.Sprintf("CREATE TABLE t%d (id integer)", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
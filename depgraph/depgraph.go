@@ -0,0 +1,220 @@
+// Package depgraph models dependency relationships between database objects
+// (tables, views, functions, procedures) and provides topological ordering,
+// cycle detection and transitive-dependency queries over them.
+package depgraph
+
+import "fmt"
+
+// Node describes one object tracked in a DependencyGraph.
+type Node struct {
+	Name     string
+	Database string
+	Schema   string
+	Type     string // Table, View, Function, Procedure
+}
+
+// DependencyGraph is a directed graph of database objects, where an edge
+// from A to B means "A depends on B" (B must exist/be created before A).
+type DependencyGraph struct {
+	nodes map[string]Node
+	edges map[string][]string
+}
+
+// New returns an empty DependencyGraph.
+func New() *DependencyGraph {
+	return &DependencyGraph{
+		nodes: make(map[string]Node),
+		edges: make(map[string][]string),
+	}
+}
+
+// AddNode registers an object in the graph. Adding a node twice overwrites
+// its metadata.
+func (g *DependencyGraph) AddNode(n Node) {
+	g.nodes[n.Name] = n
+}
+
+// AddEdge records that from depends on to. Both ends are expected to have
+// been registered via AddNode; edges to unknown nodes are ignored by
+// TopologicalOrder/Cycles since there's nothing to order them against.
+func (g *DependencyGraph) AddEdge(from, to string) {
+	if from == to {
+		return
+	}
+	g.edges[from] = append(g.edges[from], to)
+}
+
+// Nodes returns the registered nodes, keyed by name.
+func (g *DependencyGraph) Nodes() map[string]Node {
+	return g.nodes
+}
+
+// Edges returns the dependency edges, keyed by the dependent node name, with
+// values being the names it depends on.
+func (g *DependencyGraph) Edges() map[string][]string {
+	return g.edges
+}
+
+// TopologicalOrder returns the nodes ordered so that every dependency comes
+// before the nodes that depend on it (Kahn's algorithm). It returns an error
+// if the graph contains a cycle, since no valid order exists in that case —
+// use Cycles() to find and break it first.
+func (g *DependencyGraph) TopologicalOrder() ([]string, error) {
+	inDegree := make(map[string]int, len(g.nodes))
+	for name := range g.nodes {
+		inDegree[name] = 0
+	}
+	for from, tos := range g.edges {
+		if _, ok := g.nodes[from]; !ok {
+			continue
+		}
+		for _, to := range tos {
+			if _, ok := g.nodes[to]; !ok {
+				continue
+			}
+			inDegree[from]++
+		}
+	}
+
+	// dependents[x] = nodes that depend on x, i.e. edges x -> dependent
+	dependents := make(map[string][]string)
+	for from, tos := range g.edges {
+		for _, to := range tos {
+			dependents[to] = append(dependents[to], from)
+		}
+	}
+
+	var queue []string
+	for name, deg := range inDegree {
+		if deg == 0 {
+			queue = append(queue, name)
+		}
+	}
+
+	var order []string
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(g.nodes) {
+		return order, fmt.Errorf("depgraph: cycle detected, %d of %d nodes could not be ordered", len(g.nodes)-len(order), len(g.nodes))
+	}
+	return order, nil
+}
+
+// Cycles returns the strongly connected components of size greater than one
+// (found via Tarjan's algorithm), i.e. the groups of nodes involved in a
+// circular dependency. A clean DAG returns nil.
+func (g *DependencyGraph) Cycles() [][]string {
+	t := &tarjan{
+		graph:   g,
+		index:   make(map[string]int),
+		lowlink: make(map[string]int),
+		onStack: make(map[string]bool),
+	}
+	for name := range g.nodes {
+		if _, visited := t.index[name]; !visited {
+			t.strongConnect(name)
+		}
+	}
+
+	var cycles [][]string
+	for _, scc := range t.sccs {
+		if len(scc) > 1 {
+			cycles = append(cycles, scc)
+		} else if len(scc) == 1 {
+			// a single-node SCC is only a cycle if it has a self-loop
+			for _, to := range g.edges[scc[0]] {
+				if to == scc[0] {
+					cycles = append(cycles, scc)
+					break
+				}
+			}
+		}
+	}
+	return cycles
+}
+
+// tarjan holds the working state for Tarjan's strongly-connected-components
+// algorithm over a DependencyGraph.
+type tarjan struct {
+	graph   *DependencyGraph
+	index   map[string]int
+	lowlink map[string]int
+	onStack map[string]bool
+	stack   []string
+	counter int
+	sccs    [][]string
+}
+
+func (t *tarjan) strongConnect(v string) {
+	t.index[v] = t.counter
+	t.lowlink[v] = t.counter
+	t.counter++
+	t.stack = append(t.stack, v)
+	t.onStack[v] = true
+
+	for _, w := range t.graph.edges[v] {
+		if _, ok := t.graph.nodes[w]; !ok {
+			continue
+		}
+		if _, visited := t.index[w]; !visited {
+			t.strongConnect(w)
+			if t.lowlink[w] < t.lowlink[v] {
+				t.lowlink[v] = t.lowlink[w]
+			}
+		} else if t.onStack[w] {
+			if t.index[w] < t.lowlink[v] {
+				t.lowlink[v] = t.index[w]
+			}
+		}
+	}
+
+	if t.lowlink[v] == t.index[v] {
+		var scc []string
+		for {
+			n := len(t.stack) - 1
+			w := t.stack[n]
+			t.stack = t.stack[:n]
+			t.onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		t.sccs = append(t.sccs, scc)
+	}
+}
+
+// TransitiveDeps returns every node transitively reachable from name via
+// dependency edges (name's dependencies, their dependencies, and so on).
+// name itself is not included.
+func (g *DependencyGraph) TransitiveDeps(name string) []string {
+	visited := make(map[string]bool)
+	var walk func(string)
+	walk = func(n string) {
+		for _, dep := range g.edges[n] {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			walk(dep)
+		}
+	}
+	walk(name)
+	delete(visited, name)
+
+	deps := make([]string, 0, len(visited))
+	for dep := range visited {
+		deps = append(deps, dep)
+	}
+	return deps
+}
@@ -0,0 +1,122 @@
+package depgraph
+
+import (
+	"sort"
+	"testing"
+)
+
+func newGraph(names ...string) *DependencyGraph {
+	g := New()
+	for _, name := range names {
+		g.AddNode(Node{Name: name, Type: "Table"})
+	}
+	return g
+}
+
+func TestTopologicalOrderOrdersDependenciesFirst(t *testing.T) {
+	g := newGraph("a", "b", "c")
+	g.AddEdge("a", "b") // a depends on b
+	g.AddEdge("b", "c") // b depends on c
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		pos[name] = i
+	}
+	if pos["c"] >= pos["b"] || pos["b"] >= pos["a"] {
+		t.Fatalf("expected order c, b, a (dependencies first); got %v", order)
+	}
+}
+
+func TestTopologicalOrderErrorsOnCycle(t *testing.T) {
+	g := newGraph("a", "b")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "a")
+
+	if _, err := g.TopologicalOrder(); err == nil {
+		t.Fatal("expected an error for a cyclic graph")
+	}
+}
+
+func TestTopologicalOrderIgnoresEdgesToUnknownNodes(t *testing.T) {
+	g := newGraph("a")
+	g.AddEdge("a", "missing")
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(order) != 1 || order[0] != "a" {
+		t.Fatalf("expected [a], got %v", order)
+	}
+}
+
+func TestCyclesFindsStronglyConnectedComponent(t *testing.T) {
+	g := newGraph("a", "b", "c", "d")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "a")
+	g.AddEdge("a", "d") // d is not part of the cycle
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 {
+		t.Fatalf("expected exactly one cycle, got %d: %v", len(cycles), cycles)
+	}
+
+	got := append([]string(nil), cycles[0]...)
+	sort.Strings(got)
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("expected cycle %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected cycle %v, got %v", want, got)
+		}
+	}
+}
+
+func TestCyclesIgnoresSelfLoopViaAddEdge(t *testing.T) {
+	// AddEdge drops from == to edges, so a self-reference never becomes a
+	// reported cycle.
+	g := newGraph("a")
+	g.AddEdge("a", "a")
+
+	if cycles := g.Cycles(); cycles != nil {
+		t.Fatalf("expected no cycles, got %v", cycles)
+	}
+}
+
+func TestCyclesReturnsNilForDAG(t *testing.T) {
+	g := newGraph("a", "b", "c")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+
+	if cycles := g.Cycles(); cycles != nil {
+		t.Fatalf("expected nil for an acyclic graph, got %v", cycles)
+	}
+}
+
+func TestTransitiveDeps(t *testing.T) {
+	g := newGraph("a", "b", "c", "d")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("a", "d")
+
+	deps := g.TransitiveDeps("a")
+	sort.Strings(deps)
+
+	want := []string{"b", "c", "d"}
+	if len(deps) != len(want) {
+		t.Fatalf("expected %v, got %v", want, deps)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, deps)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Dialect abstracts the database-specific queries and type mapping needed to
+// introspect tables, views, columns and dependencies. Each supported
+// DBType (mssql, sqlite, mysql, postgres) gets its own implementation so the
+// query layer in queryDatabasesStream/queryTableDefinition/etc. stays dialect-agnostic.
+type Dialect interface {
+	// Driver returns the database/sql driver name to use with sql.Open.
+	Driver() string
+	// DSN builds the connection string for the given server/credentials.
+	DSN(server, user, password, database string) string
+	// QueryTables returns the query listing tables, views, functions and
+	// procedures for the given database.
+	QueryTables(database string) string
+	// QueryColumns returns the query listing the columns of the given table.
+	QueryColumns(database, schema, table string) string
+	// QueryViewDefinition returns the query fetching the definition/body of
+	// the given view (or routine).
+	QueryViewDefinition(database, schema, table string) string
+	// QueryDependencies returns the query listing objects referenced by the
+	// given table/view.
+	QueryDependencies(database, schema, table string) string
+	// ScanColumns reads the rows produced by QueryColumns into Columns.
+	ScanColumns(rows *sql.Rows) ([]Column, error)
+	// ScanTableRef reads one row produced by QueryTables into the object's
+	// schema, name and type (BASE TABLE/VIEW/FUNCTION/PROCEDURE).
+	ScanTableRef(rows *sql.Rows) (schema, name, objectType string, err error)
+	// MapType maps a dialect-native column type name to a Go type.
+	MapType(sqlType string) string
+}
+
+// dialectFor returns the Dialect implementation matching config.DBType,
+// defaulting to MSSQL for backwards compatibility with existing configs.
+func dialectFor(dbType string) Dialect {
+	switch dbType {
+	case "sqlite":
+		return sqliteDialect{}
+	case "mysql":
+		return mysqlDialect{}
+	case "postgres", "postgresql":
+		return postgresDialect{}
+	default:
+		return mssqlDialect{}
+	}
+}
+
+// mssqlDialect implements Dialect for Microsoft SQL Server.
+type mssqlDialect struct{}
+
+func (mssqlDialect) Driver() string { return "mssql" }
+
+func (mssqlDialect) DSN(server, user, password, database string) string {
+	return fmt.Sprintf("server=%s;user id=%s;password=%s;port=1433", server, user, password)
+}
+
+func (mssqlDialect) QueryTables(database string) string {
+	return fmt.Sprintf("SELECT TABLE_CATALOG, TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM [%s].INFORMATION_SCHEMA.TABLES UNION ALL SELECT ROUTINE_CATALOG, ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE FROM [%s].INFORMATION_SCHEMA.ROUTINES", database, database)
+}
+
+func (mssqlDialect) QueryColumns(database, schema, table string) string {
+	return fmt.Sprintf("USE %s; SELECT c.Name, [Type_Name] = tp.name, c.Max_Length, c.[Precision], c.Scale, ISNULL(c.Collation_Name, '') as Collation_Name, c.Is_Nullable, c.Is_Identity FROM sys.columns c WITH(NOLOCK) JOIN sys.types tp WITH(NOLOCK) ON c.user_type_id = tp.user_type_id WHERE c.[object_id] = OBJECT_ID(N'[%s].[%s].[%s]')", database, database, schema, table)
+}
+
+func (mssqlDialect) QueryViewDefinition(database, schema, table string) string {
+	return fmt.Sprintf("USE %s; SELECT ISNULL(OBJECT_DEFINITION(OBJECT_ID(N'[%s].[%s].[%s]')),'') as [definition]", database, database, schema, table)
+}
+
+func (mssqlDialect) QueryDependencies(database, schema, table string) string {
+	return fmt.Sprintf("SELECT ISNULL(referenced_database_name, '') as referenced_database_name, ISNULL(referenced_schema_name,'') as referenced_schema_name, ISNULL(referenced_entity_name,'') as referenced_entity_name FROM [%s].sys.sql_expression_dependencies WHERE referencing_id = OBJECT_ID(N'[%s].[%s].[%s]')", database, database, schema, table)
+}
+
+func (mssqlDialect) ScanColumns(rows *sql.Rows) ([]Column, error) {
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		if err := rows.Scan(&col.Name, &col.Type_Name, &col.Max_Length, &col.Precision, &col.Scale, &col.Collation_Name, &col.Is_Nullable, &col.Is_Identity); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (mssqlDialect) ScanTableRef(rows *sql.Rows) (schema, name, objectType string, err error) {
+	var catalog string
+	err = rows.Scan(&catalog, &schema, &name, &objectType)
+	return schema, name, objectType, err
+}
+
+func (mssqlDialect) MapType(sqlType string) string {
+	typeMap := map[string]string{
+		"int":              "int",
+		"bigint":           "int64",
+		"smallint":         "int16",
+		"tinyint":          "int8",
+		"varchar":          "string",
+		"nvarchar":         "string",
+		"char":             "string",
+		"nchar":            "string",
+		"text":             "string",
+		"ntext":            "string",
+		"datetime":         "time.Time",
+		"datetime2":        "time.Time",
+		"date":             "time.Time",
+		"bit":              "bool",
+		"float":            "float64",
+		"real":             "float32",
+		"decimal":          "float64",
+		"numeric":          "float64",
+		"money":            "float64",
+		"uniqueidentifier": "string",
+	}
+	if goType, found := typeMap[sqlType]; found {
+		return goType
+	}
+	return "interface{}"
+}
+
+// sqliteDialect implements Dialect for SQLite.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Driver() string { return "sqlite3" }
+
+func (sqliteDialect) DSN(server, user, password, database string) string {
+	return server
+}
+
+func (sqliteDialect) QueryTables(database string) string {
+	return "SELECT name, type FROM sqlite_master WHERE type IN ('table', 'view')"
+}
+
+func (sqliteDialect) QueryColumns(database, schema, table string) string {
+	return fmt.Sprintf("PRAGMA table_info(%s)", table)
+}
+
+func (sqliteDialect) QueryViewDefinition(database, schema, table string) string {
+	return fmt.Sprintf("SELECT sql FROM sqlite_master WHERE name='%s' AND type='view'", table)
+}
+
+func (sqliteDialect) QueryDependencies(database, schema, table string) string {
+	// SQLite doesn't support this
+	return "SELECT '' as referenced_database_name, '' as referenced_schema_name, '' as referenced_entity_name"
+}
+
+func (sqliteDialect) ScanColumns(rows *sql.Rows) ([]Column, error) {
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		var cid, notnull, dfltValue, pk int
+		if err := rows.Scan(&cid, &col.Name, &col.Type_Name, &notnull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		col.Is_Nullable = notnull == 0
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (sqliteDialect) ScanTableRef(rows *sql.Rows) (schema, name, objectType string, err error) {
+	// sqlite_master has no schema column; tables live in the single "main" schema.
+	err = rows.Scan(&name, &objectType)
+	return "", name, objectType, err
+}
+
+func (sqliteDialect) MapType(sqlType string) string {
+	typeMap := map[string]string{
+		"integer":  "int",
+		"int":      "int",
+		"text":     "string",
+		"varchar":  "string",
+		"real":     "float64",
+		"float":    "float64",
+		"blob":     "[]byte",
+		"boolean":  "bool",
+		"datetime": "time.Time",
+	}
+	if goType, found := typeMap[sqlType]; found {
+		return goType
+	}
+	return "interface{}"
+}
+
+// mysqlDialect implements Dialect for MySQL/MariaDB.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Driver() string { return "mysql" }
+
+func (mysqlDialect) DSN(server, user, password, database string) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:3306)/", user, password, server)
+}
+
+func (mysqlDialect) QueryTables(database string) string {
+	return fmt.Sprintf("SELECT TABLE_CATALOG, TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM information_schema.TABLES WHERE TABLE_SCHEMA = '%s' UNION ALL SELECT ROUTINE_CATALOG, ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA = '%s'", database, database)
+}
+
+func (mysqlDialect) QueryColumns(database, schema, table string) string {
+	return fmt.Sprintf("SELECT COLUMN_NAME, DATA_TYPE, IFNULL(CHARACTER_MAXIMUM_LENGTH, 0), IFNULL(NUMERIC_PRECISION, 0), IFNULL(NUMERIC_SCALE, 0), IFNULL(COLLATION_NAME, ''), IF(IS_NULLABLE = 'YES', 1, 0), IF(EXTRA = 'auto_increment', 1, 0), COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' ORDER BY ORDINAL_POSITION", database, table)
+}
+
+func (mysqlDialect) QueryViewDefinition(database, schema, table string) string {
+	return fmt.Sprintf("SELECT IFNULL(VIEW_DEFINITION, '') FROM information_schema.VIEWS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'", database, table)
+}
+
+func (mysqlDialect) QueryDependencies(database, schema, table string) string {
+	return fmt.Sprintf("SELECT IFNULL(REFERENCED_TABLE_SCHEMA, ''), '', IFNULL(REFERENCED_TABLE_NAME, '') FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND REFERENCED_TABLE_NAME IS NOT NULL", database, table)
+}
+
+func (mysqlDialect) ScanColumns(rows *sql.Rows) ([]Column, error) {
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		var columnType string
+		if err := rows.Scan(&col.Name, &col.Type_Name, &col.Max_Length, &col.Precision, &col.Scale, &col.Collation_Name, &col.Is_Nullable, &col.Is_Identity, &columnType); err != nil {
+			return nil, err
+		}
+		// DATA_TYPE reports the bare "tinyint" even for boolean-style
+		// tinyint(1) columns; the display width only shows up in
+		// COLUMN_TYPE, so recover it here for the MapType "tinyint(1)"->bool entry.
+		if col.Type_Name == "tinyint" && columnType == "tinyint(1)" {
+			col.Type_Name = columnType
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (mysqlDialect) ScanTableRef(rows *sql.Rows) (schema, name, objectType string, err error) {
+	var catalog string
+	err = rows.Scan(&catalog, &schema, &name, &objectType)
+	return schema, name, objectType, err
+}
+
+func (mysqlDialect) MapType(sqlType string) string {
+	typeMap := map[string]string{
+		"int":        "int",
+		"bigint":     "int64",
+		"smallint":   "int16",
+		"tinyint":    "int8",
+		"varchar":    "string",
+		"char":       "string",
+		"text":       "string",
+		"longtext":   "string",
+		"mediumtext": "string",
+		"json":       "string",
+		"datetime":   "time.Time",
+		"timestamp":  "time.Time",
+		"date":       "time.Time",
+		"tinyint(1)": "bool",
+		"float":      "float64",
+		"double":     "float64",
+		"decimal":    "float64",
+	}
+	if goType, found := typeMap[sqlType]; found {
+		return goType
+	}
+	return "interface{}"
+}
+
+// postgresDialect implements Dialect for PostgreSQL.
+type postgresDialect struct{}
+
+func (postgresDialect) Driver() string { return "postgres" }
+
+func (postgresDialect) DSN(server, user, password, database string) string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=disable", server, user, password, database)
+}
+
+func (postgresDialect) QueryTables(database string) string {
+	return "SELECT table_catalog, table_schema, table_name, table_type FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema') UNION ALL SELECT routine_catalog, routine_schema, routine_name, routine_type FROM information_schema.routines WHERE routine_schema NOT IN ('pg_catalog', 'information_schema')"
+}
+
+func (postgresDialect) QueryColumns(database, schema, table string) string {
+	return fmt.Sprintf("SELECT column_name, data_type, COALESCE(character_maximum_length, 0), COALESCE(numeric_precision, 0), COALESCE(numeric_scale, 0), COALESCE(collation_name, ''), CASE WHEN is_nullable = 'YES' THEN true ELSE false END, CASE WHEN column_default LIKE 'nextval%%' THEN true ELSE false END FROM information_schema.columns WHERE table_schema = '%s' AND table_name = '%s' ORDER BY ordinal_position", schema, table)
+}
+
+func (postgresDialect) QueryViewDefinition(database, schema, table string) string {
+	return fmt.Sprintf("SELECT COALESCE(pg_get_viewdef('%s.%s'::regclass, true), '')", schema, table)
+}
+
+func (postgresDialect) QueryDependencies(database, schema, table string) string {
+	return fmt.Sprintf(`SELECT '' as referenced_database_name, dn.nspname as referenced_schema_name, dc.relname as referenced_entity_name
+FROM pg_depend d
+JOIN pg_rewrite r ON r.oid = d.objid
+JOIN pg_class c ON c.oid = r.ev_class
+JOIN pg_class dc ON dc.oid = d.refobjid
+JOIN pg_namespace dn ON dn.oid = dc.relnamespace
+WHERE c.relname = '%s' AND dc.relname != '%s'`, table, table)
+}
+
+func (postgresDialect) ScanColumns(rows *sql.Rows) ([]Column, error) {
+	var columns []Column
+	for rows.Next() {
+		var col Column
+		if err := rows.Scan(&col.Name, &col.Type_Name, &col.Max_Length, &col.Precision, &col.Scale, &col.Collation_Name, &col.Is_Nullable, &col.Is_Identity); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (postgresDialect) ScanTableRef(rows *sql.Rows) (schema, name, objectType string, err error) {
+	var catalog string
+	err = rows.Scan(&catalog, &schema, &name, &objectType)
+	return schema, name, objectType, err
+}
+
+func (postgresDialect) MapType(sqlType string) string {
+	typeMap := map[string]string{
+		"integer":                     "int",
+		"bigint":                      "int64",
+		"smallint":                    "int16",
+		"text":                        "string",
+		"character varying":           "string",
+		"character":                   "string",
+		"uuid":                        "string",
+		"json":                        "string",
+		"jsonb":                       "string",
+		"timestamp without time zone": "time.Time",
+		"timestamp with time zone":    "time.Time",
+		"timestamptz":                 "time.Time",
+		"date":                        "time.Time",
+		"boolean":                     "bool",
+		"real":                        "float32",
+		"double precision":            "float64",
+		"numeric":                     "float64",
+	}
+	if goType, found := typeMap[sqlType]; found {
+		return goType
+	}
+	return "interface{}"
+}
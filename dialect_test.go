@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMapTypePostgres(t *testing.T) {
+	dialect := postgresDialect{}
+
+	cases := map[string]string{
+		"integer":           "int",
+		"character varying": "string",
+		"timestamptz":       "time.Time",
+		"boolean":           "bool",
+		"unknown_type":      "interface{}",
+	}
+	for sqlType, want := range cases {
+		if got := dialect.MapType(sqlType); got != want {
+			t.Errorf("postgresDialect.MapType(%q) = %q, want %q", sqlType, got, want)
+		}
+	}
+}
+
+func TestMapTypeMSSQL(t *testing.T) {
+	dialect := mssqlDialect{}
+
+	cases := map[string]string{
+		"int":              "int",
+		"uniqueidentifier": "string",
+		"datetime2":        "time.Time",
+		"bit":              "bool",
+		"unknown_type":     "interface{}",
+	}
+	for sqlType, want := range cases {
+		if got := dialect.MapType(sqlType); got != want {
+			t.Errorf("mssqlDialect.MapType(%q) = %q, want %q", sqlType, got, want)
+		}
+	}
+}
+
+func TestMapTypeSQLite(t *testing.T) {
+	dialect := sqliteDialect{}
+
+	cases := map[string]string{
+		"integer":      "int",
+		"text":         "string",
+		"blob":         "[]byte",
+		"boolean":      "bool",
+		"unknown_type": "interface{}",
+	}
+	for sqlType, want := range cases {
+		if got := dialect.MapType(sqlType); got != want {
+			t.Errorf("sqliteDialect.MapType(%q) = %q, want %q", sqlType, got, want)
+		}
+	}
+}
+
+// selectColumnCount returns the number of columns in the first SELECT clause
+// of a query, i.e. how many values a caller must Scan to read one row. This
+// is how the mssql/mysql/postgres cases below catch a QueryTables/ScanTableRef
+// mismatch without needing a live database connection per dialect.
+func selectColumnCount(t *testing.T, query string) int {
+	t.Helper()
+	upper := strings.ToUpper(query)
+	start := strings.Index(upper, "SELECT")
+	end := strings.Index(upper, " FROM")
+	if start == -1 || end == -1 || end < start {
+		t.Fatalf("could not find a SELECT ... FROM clause in query: %s", query)
+	}
+	list := query[start+len("SELECT") : end]
+	return len(strings.Split(list, ","))
+}
+
+// TestScanTableRefMatchesQueryTablesColumnCount guards against the class of
+// bug fixed in chunk0-1: QueryTables returning more columns than
+// ScanTableRef reads, which errors on every single row. mssql/mysql/postgres
+// all list (catalog, schema, name, type); sqlite_master has no catalog/schema
+// columns, so sqliteDialect's pair is checked against a real query instead.
+func TestScanTableRefMatchesQueryTablesColumnCount(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect Dialect
+		want    int
+	}{
+		{"mssql", mssqlDialect{}, 4},
+		{"mysql", mysqlDialect{}, 4},
+		{"postgres", postgresDialect{}, 4},
+	}
+	for _, c := range cases {
+		if got := selectColumnCount(t, c.dialect.QueryTables("db")); got != c.want {
+			t.Errorf("%s QueryTables selects %d column(s), want %d to match ScanTableRef", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSQLiteScanTableRefMatchesQueryTables(t *testing.T) {
+	path := t.TempDir() + "/dialect.db"
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	defer os.Remove(path)
+
+	if _, err := db.Exec("CREATE TABLE widgets (id integer)"); err != nil {
+		t.Fatal(err)
+	}
+
+	dialect := sqliteDialect{}
+	rows, err := db.Query(dialect.QueryTables("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatal("expected at least one row from QueryTables")
+	}
+	schema, name, objectType, err := dialect.ScanTableRef(rows)
+	if err != nil {
+		t.Fatalf("ScanTableRef: %v", err)
+	}
+	if schema != "" || name != "widgets" || objectType != "table" {
+		t.Fatalf("got schema=%q name=%q type=%q, want schema=\"\" name=widgets type=table", schema, name, objectType)
+	}
+}
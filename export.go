@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,35 +24,41 @@ func createDirectory(dir string) {
 }
 
 // writeSQLFile writes the SQL file, info file, and Go struct file for a given view.
-func writeSQLFile(dir string, view TableInfo) {
-	var err error
+func writeSQLFile(dir string, view TableInfo, dialect Dialect, structOpts StructOptions, templates *TemplateSet) {
 	if len(view.Definition) > 10 {
-		infofile := generateInfoFile(view)
-		if len(view.Definition) > 10 {
-			err = os.WriteFile(dir+view.TableName+".sql", []byte(view.Definition), 0644)
-			if err != nil {
-				fmt.Println("Error writing SQL file:", err)
-			}
+		sqlfile, err := templates.render("view.sql.tmpl", view)
+		if err != nil {
+			fmt.Println("Error rendering SQL file:", err)
+		} else if err = os.WriteFile(dir+view.TableName+".sql", []byte(sqlfile), 0644); err != nil {
+			fmt.Println("Error writing SQL file:", err)
 		}
-		if len(infofile) > 10 {
-			err = os.WriteFile(dir+view.TableName+".info.md", []byte(infofile), 0644)
-			if err != nil {
+
+		infofile, err := generateInfoFile(view, templates)
+		if err != nil {
+			fmt.Println("Error rendering info file:", err)
+		} else if len(infofile) > 10 {
+			if err = os.WriteFile(dir+view.TableName+".info.md", []byte(infofile), 0644); err != nil {
 				fmt.Println("Error writing info file:", err)
 			}
 		}
 	} else if len(view.Database) > 1 {
-		infofile := generateTableInfoFile(view)
-		if len(infofile) > 10 {
-			err := os.WriteFile(dir+cleanFn(view.TableName)+".info.md", []byte(infofile), 0644)
-			if err != nil {
+		infofile, err := generateTableInfoFile(view, templates)
+		if err != nil {
+			fmt.Println("Error rendering info file:", err)
+		} else if len(infofile) > 10 {
+			if err = os.WriteFile(dir+cleanFn(view.TableName)+".info.md", []byte(infofile), 0644); err != nil {
 				fmt.Println("Error writing info file:", err)
 			}
 		}
 	}
-	structFile := generateGoStruct(view)
+
+	structFile, err := generateGoStruct(view, dialect, structOpts, templates)
+	if err != nil {
+		fmt.Println("Error rendering Go struct file:", err)
+		return
+	}
 	if len(structFile) > 10 {
-		err = os.WriteFile(dir+view.TableName+".go", []byte(structFile), 0644)
-		if err != nil {
+		if err = os.WriteFile(dir+view.TableName+".go", []byte(structFile), 0644); err != nil {
 			fmt.Println("Error writing Go struct file:", err)
 		}
 	}
@@ -75,28 +83,35 @@ func extractDataFromComment(comment string) (string, time.Time, string) {
 	return strings.TrimSpace(creator), creationDateTime, strings.TrimSpace(commentText)
 }
 
+// infoTemplateData is the data passed to the info.md.tmpl template: the view
+// itself plus the bits that used to be computed inline (comment text, commit
+// log lines, the rendered column table).
+type infoTemplateData struct {
+	TableInfo
+	CommentText string
+	CommitLines []string
+	StructTable string
+}
+
 // generateInfoFile generates an information file for a given view.
-func generateInfoFile(view TableInfo) string {
-	sqllines := strings.Split(view.Definition, "\n")
+func generateInfoFile(view TableInfo, templates *TemplateSet) (string, error) {
 	_, _, commentText := extractDataFromComment(view.Definition)
 
-	infofile := "# Infodatei zum View [" + strings.ToLower(view.Database+"."+view.Schema) + "." + view.TableName + "](../../" + strings.ToLower(view.Database+"/"+view.Schema) + "/" + view.TableName + ".sql)\n\n" + commentText + "\n\n"
-	infofile += "## Tabellenstruktur\n\n" + generateTableStructTable(view) + "\n\n## Änderungen\n\nBenutzer|Datum|Kommentar\n--|--|--\n"
-
-	for _, l := range sqllines {
+	var commitLines []string
+	for _, l := range strings.Split(view.Definition, "\n") {
 		l = strings.TrimSpace(l)
 		if strings.HasPrefix(l, "Commit;") {
 			l = strings.TrimPrefix(l, "Commit;")
-			infofile += strings.ReplaceAll(l, ";", "|") + "\n"
+			commitLines = append(commitLines, strings.ReplaceAll(l, ";", "|"))
 		}
 	}
-	infofile += "\n" + "## Abhängigkeiten" + "\n\n" + "DB|Schema|Tabelle/View" + "\n" + "--|--|--" + "\n"
-	for _, dep := range view.Dependencies {
-		infofile += strings.ToLower(dep.ReferencedDB+"|"+dep.ReferencedSchema+"|["+dep.ReferencedTable+"](../../"+dep.ReferencedDB+"/"+dep.ReferencedSchema) + "/" + dep.ReferencedTable + ".info.md)\n"
-	}
 
-	infofile += "\n\n"
-	return infofile
+	return templates.render("info.md.tmpl", infoTemplateData{
+		TableInfo:   view,
+		CommentText: commentText,
+		CommitLines: commitLines,
+		StructTable: generateTableStructTable(view),
+	})
 }
 
 // generateTableStructTable generates a markdown-table containing the table's structure.
@@ -110,60 +125,147 @@ func generateTableStructTable(view TableInfo) string {
 	return table
 }
 
+// tableInfoTemplateData is the data passed to the table.md.tmpl template.
+type tableInfoTemplateData struct {
+	TableInfo
+	StructTable string
+}
+
 // generateTableInfoFile generates an information file for the given table.
-func generateTableInfoFile(view TableInfo) string {
-	infofile := "# Infodatei zur Tabelle " + strings.ToLower(view.Database+"."+view.Schema) + "." + view.TableName + "\n\n"
-	infofile += "## Tabellenstruktur\n\n" + generateTableStructTable(view) + view.Definition
-	return infofile
+func generateTableInfoFile(view TableInfo, templates *TemplateSet) (string, error) {
+	return templates.render("table.md.tmpl", tableInfoTemplateData{
+		TableInfo:   view,
+		StructTable: generateTableStructTable(view),
+	})
 }
 
-// generateGoStruct generates a Go struct for the given table or view.
-func generateGoStruct(view TableInfo) string {
-	structDef := "package main\n\n"
-	structDef += fmt.Sprintf("// %s represents a database table/view structure\n", view.TableName)
-	structDef += fmt.Sprintf("type %s struct {\n", view.TableName)
+// goStructTemplateData is the data passed to the struct.go.tmpl template.
+// Fields is precomputed (type, nullability, tags already resolved) so the
+// template itself stays a plain rendering step.
+type goStructTemplateData struct {
+	TableInfo
+	StructName string
+	Fields     []structField
+	Imports    []string
+}
 
-	for _, col := range view.Columns {
-		structDef += fmt.Sprintf("\t%s %s `json:\"%s\"`\n", col.Name, mapSQLTypeToGoType(col.Type_Name), col.Name)
+// generateGoStruct generates a Go struct for the given table or view,
+// honoring the tag style, nullable-wrapping and naming knobs in opts.
+func generateGoStruct(view TableInfo, dialect Dialect, opts StructOptions, templates *TemplateSet) (string, error) {
+	structName := view.TableName
+	if opts.Singularize {
+		structName = singularize(structName)
+	}
+	if opts.PascalCaseNames {
+		structName = pascalCase(structName)
 	}
-	structDef += "}\n"
-	return structDef
+
+	fields := buildStructFields(view.Columns, dialect, opts)
+
+	return templates.render("struct.go.tmpl", goStructTemplateData{
+		TableInfo:  view,
+		StructName: structName,
+		Fields:     fields,
+		Imports:    structImports(fields),
+	})
 }
 
-// mapSQLTypeToGoType maps SQL types to Go types.
-func mapSQLTypeToGoType(sqlType string) string {
-	typeMap := map[string]string{
-		"int":        "int",
-		"varchar":    "string",
-		"nvarchar":   "string",
-		"datetime":   "time.Time",
-		"bit":        "bool",
-		"float":      "float64",
-		"decimal":    "float64",
-		// Add more SQL to Go type mappings as needed
+// exportToFiles exports the given list of TableInfo to files. templateDir, if
+// non-empty, points at a directory of *.tmpl files that override the
+// embedded defaults (info.md.tmpl, table.md.tmpl, struct.go.tmpl, view.sql.tmpl).
+func exportToFiles(j []TableInfo, config Config, templateDir string) error {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Fehler beim Ermitteln des aktuellen Verzeichnisses: %v\n", err)
+		return err
 	}
 
-	if goType, found := typeMap[sqlType]; found {
-		return goType
+	dialect := dialectFor(config.DBType)
+
+	templates, err := loadTemplates(templateDir)
+	if err != nil {
+		return err
 	}
-	return "interface{}"
+
+	for _, view := range j {
+		exportTableInfo(workingDir, view, dialect, config.StructOptions, templates)
+	}
+	return nil
 }
 
-// exportToFiles exports the given list of TableInfo to files.
-func exportToFiles(j []TableInfo) error {
+// exportTableInfo writes the SQL/info/Go-struct files for a single view
+// under workingDir/vcs/<database>/<schema>/, skipping placeholder rows with
+// no real database. It's the per-object unit exportToFiles loops over, and
+// is also called from streamToFiles' queryDatabasesStream handler (the
+// streaming "files" path) so large extractions don't need every TableInfo in
+// memory at once.
+func exportTableInfo(workingDir string, view TableInfo, dialect Dialect, structOpts StructOptions, templates *TemplateSet) {
+	if view.Database == "." || len(view.Database) < 2 {
+		return
+	}
+	dir := fmt.Sprintf("%s/vcs/%s/%s/", workingDir, strings.ToLower(view.Database), strings.ToLower(view.Schema))
+	createDirectory(dir)
+	writeSQLFile(dir, view, dialect, structOpts, templates)
+}
+
+// streamToFiles runs the extraction pipeline and writes each TableInfo's
+// files as soon as it's produced, instead of collecting the full batch into
+// memory first the way exportToFiles's caller does. It's the "files"-mode
+// consumer that queryDatabasesStream was built for. changedOnly/since apply
+// the same incremental-cache semantics as the batch path (see
+// Cache.MarkSeen).
+func streamToFiles(config Config, templateDir string, changedOnly bool, since time.Time) error {
 	workingDir, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Fehler beim Ermitteln des aktuellen Verzeichnisses: %v\n", err)
 		return err
 	}
 
-	for _, view := range j {
-		dir := fmt.Sprintf("%s/vcs/%s/%s/", workingDir, strings.ToLower(view.Database), strings.ToLower(view.Schema))
-		if view.Database == "." || len(view.Database) < 2 {
-			continue
+	dialect := dialectFor(config.DBType)
+
+	templates, err := loadTemplates(templateDir)
+	if err != nil {
+		return err
+	}
+
+	keep := newTableFilter(config.IncludeTables, config.ExcludeTables)
+
+	var cache *Cache
+	if changedOnly {
+		cache, err = openCache(cacheDBFile)
+		if err != nil {
+			return err
+		}
+		defer cache.Close()
+	}
+
+	var mu sync.Mutex
+	count := 0
+
+	err = queryDatabasesStream(config, func(info TableInfo) error {
+		if !keep(info) {
+			return nil
+		}
+		if cache != nil {
+			changed, err := cache.MarkSeen(info, since)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				return nil
+			}
 		}
-		createDirectory(dir)
-		writeSQLFile(dir, view)
+
+		exportTableInfo(workingDir, info, dialect, config.StructOptions, templates)
+
+		mu.Lock()
+		count++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+
+	log.Printf("Wrote %d object(s)", count)
 	return nil
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SimonWaldherr/DatabaseExtractor/depgraph"
+)
+
+// typeColors assigns a fill color per object Type, shared by the DOT and
+// Mermaid renderers so the graph visually separates tables from
+// views/functions/procedures.
+var typeColors = map[string]string{
+	"Table":     "#bbdefb",
+	"View":      "#c8e6c9",
+	"Function":  "#ffe0b2",
+	"Procedure": "#f8bbd0",
+}
+
+// generateDependencyGraph writes a Graphviz DOT file and a Mermaid diagram
+// of cross-object dependencies into dir, grouping objects into subgraphs by
+// database.schema and coloring nodes by object Type. Cyclic dependencies
+// (legal for MSSQL views via sql_expression_dependencies, but fatal for
+// migration ordering) are logged as warnings.
+func generateDependencyGraph(data []TableInfo, dir string) error {
+	createDirectory(dir)
+
+	g := buildDependencyGraph(data)
+
+	for _, cycle := range g.Cycles() {
+		log.Printf("Warning: cyclic dependency detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "dependencies.dot"), []byte(renderDOT(g)), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "dependencies.mmd"), []byte(renderMermaid(g)), 0644)
+}
+
+// buildDependencyGraph turns the extracted TableInfo list into a
+// depgraph.DependencyGraph keyed by table/view name.
+func buildDependencyGraph(data []TableInfo) *depgraph.DependencyGraph {
+	g := depgraph.New()
+	for _, t := range data {
+		g.AddNode(depgraph.Node{
+			Name:     t.TableName,
+			Database: t.Database,
+			Schema:   t.Schema,
+			Type:     typeMap[t.Type],
+		})
+	}
+	for _, t := range data {
+		for _, dep := range t.Dependencies {
+			g.AddEdge(t.TableName, dep.ReferencedTable)
+		}
+	}
+	return g
+}
+
+// groupKey returns the database.schema grouping key for a node.
+func groupKey(n depgraph.Node) string {
+	return strings.ToLower(n.Database + "." + n.Schema)
+}
+
+// groupNodes buckets a graph's nodes by groupKey, sorting both the group
+// keys and the nodes within each group for deterministic output.
+func groupNodes(g *depgraph.DependencyGraph) (keys []string, groups map[string][]depgraph.Node) {
+	groups = make(map[string][]depgraph.Node)
+	for _, n := range g.Nodes() {
+		groups[groupKey(n)] = append(groups[groupKey(n)], n)
+	}
+	for key, nodes := range groups {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+		groups[key] = nodes
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, groups
+}
+
+// sortedEdges returns the graph's edges as (from, to) pairs in a
+// deterministic order.
+func sortedEdges(g *depgraph.DependencyGraph) [][2]string {
+	var froms []string
+	for from := range g.Edges() {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	var edges [][2]string
+	for _, from := range froms {
+		tos := append([]string(nil), g.Edges()[from]...)
+		sort.Strings(tos)
+		for _, to := range tos {
+			edges = append(edges, [2]string{from, to})
+		}
+	}
+	return edges
+}
+
+// renderDOT renders a Graphviz DOT representation of the graph.
+func renderDOT(g *depgraph.DependencyGraph) string {
+	keys, groups := groupNodes(g)
+
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("    rankdir=LR;\n")
+
+	for i, key := range keys {
+		fmt.Fprintf(&b, "    subgraph cluster_%d {\n        label=%q;\n", i, key)
+		for _, n := range groups[key] {
+			color := typeColors[n.Type]
+			if color == "" {
+				color = "#eeeeee"
+			}
+			fmt.Fprintf(&b, "        %q [style=filled, fillcolor=%q];\n", n.Name, color)
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, edge := range sortedEdges(g) {
+		fmt.Fprintf(&b, "    %q -> %q;\n", edge[0], edge[1])
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderMermaid renders a Mermaid flowchart representation of the graph.
+func renderMermaid(g *depgraph.DependencyGraph) string {
+	keys, groups := groupNodes(g)
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, "    subgraph %s[\"%s\"]\n", mermaidID("cluster_"+key), key)
+		for _, n := range groups[key] {
+			fmt.Fprintf(&b, "        %s[\"%s\"]\n", mermaidID(n.Name), n.Name)
+		}
+		b.WriteString("    end\n")
+	}
+
+	for _, edge := range sortedEdges(g) {
+		fmt.Fprintf(&b, "    %s --> %s\n", mermaidID(edge[0]), mermaidID(edge[1]))
+	}
+
+	for _, n := range g.Nodes() {
+		if color := typeColors[n.Type]; color != "" {
+			fmt.Fprintf(&b, "    style %s fill:%s\n", mermaidID(n.Name), color)
+		}
+	}
+
+	return b.String()
+}
+
+// mermaidID sanitizes a node name into a valid, unique-enough Mermaid node
+// identifier (Mermaid IDs can't contain ".", "/" or whitespace).
+func mermaidID(name string) string {
+	replacer := strings.NewReplacer(".", "_", "/", "_", " ", "_", "-", "_")
+	return replacer.Replace(name)
+}
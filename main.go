@@ -6,6 +6,7 @@ import (
 	"flag"
 	"log"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -15,17 +16,41 @@ type Config struct {
 	Server    string   `yaml:"server"`
 	User      string   `yaml:"user"`
 	Password  string   `yaml:"password"`
-	DBType    string   `yaml:"dbtype"`
+	DBType    string   `yaml:"dbtype"` // one of "mssql" (default), "sqlite", "mysql", "postgres"
 	Databases []string `yaml:"databases"`
 	IncludeTables []string `yaml:"include_tables"`
 	ExcludeTables []string `yaml:"exclude_tables"`
+	StructOptions StructOptions `yaml:"struct_options"`
+	Concurrency int `yaml:"concurrency"` // worker pool size for queryDatabasesStream; defaults to 4 when <= 0
+}
+
+// StructOptions controls how generateGoStruct renders Go structs for tables
+// and views.
+type StructOptions struct {
+	// Tags selects which struct tags to emit, in order, e.g. [gorm, json].
+	// Supported values: "gorm", "xorm", "db", "json". Defaults to ["json"].
+	Tags []string `yaml:"struct_tags"`
+	// NullableStyle controls how nullable columns are represented: "pointer"
+	// (default) emits a pointer to the base Go type, "sql.Null" emits the
+	// matching database/sql.NullXxx wrapper type instead.
+	NullableStyle string `yaml:"nullable_style"`
+	// PascalCaseNames converts table and column identifiers to PascalCase
+	// for the generated struct and field names.
+	PascalCaseNames bool `yaml:"pascal_case_names"`
+	// Singularize converts a plural table name to its singular form before
+	// deriving the struct name (e.g. table "users" -> struct "User"), the
+	// convention ORM reverse-generators like xorm and beego's g_mvcgen.go
+	// use. It has no effect on column/field names.
+	Singularize bool `yaml:"singularize_struct_names"`
 }
 
 func main() {
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
-	outputType := flag.String("output", "debug", "output type [json,xml,files,debug]")
+	outputType := flag.String("output", "debug", "output type [json,xml,files,migrations,migrations-diff,graph,debug]")
 	useCached := flag.Bool("cached", false, "use cached data")
-	templateFile := flag.String("template", "", "Path to custom template file")
+	templateDir := flag.String("template", "", "Path to a directory of custom *.tmpl files overriding the built-in output templates")
+	changedOnly := flag.Bool("changed-only", false, "only keep objects that changed since the last extraction (tracked in "+cacheDBFile+")")
+	since := flag.String("since", "", "RFC3339 timestamp; with -changed-only, also treat objects last seen before this time as changed")
 	flag.Parse()
 
 	config, err := loadConfig(*configFile)
@@ -33,6 +58,24 @@ func main() {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
+	var sinceTime time.Time
+	if *since != "" {
+		sinceTime, err = time.Parse(time.RFC3339, *since)
+		if err != nil {
+			log.Fatalf("Error parsing -since: %v", err)
+		}
+	}
+
+	// "files" output can be produced straight off the extraction pipeline,
+	// one TableInfo at a time, instead of collecting every object into
+	// memory first -- that's what queryDatabasesStream is for.
+	if *outputType == "files" && !*useCached {
+		if err := streamToFiles(config, *templateDir, *changedOnly, sinceTime); err != nil {
+			log.Fatalf("Error writing files: %v", err)
+		}
+		return
+	}
+
 	var data []TableInfo
 
 	if *useCached {
@@ -50,6 +93,20 @@ func main() {
 	// Filter data based on include/exclude lists
 	data = filterData(data, config.IncludeTables, config.ExcludeTables)
 
+	if *changedOnly {
+		cache, err := openCache(cacheDBFile)
+		if err != nil {
+			log.Fatalf("Error opening cache: %v", err)
+		}
+		defer cache.Close()
+
+		data, err = cache.FilterChanged(data, sinceTime)
+		if err != nil {
+			log.Fatalf("Error filtering changed objects: %v", err)
+		}
+		log.Printf("%d object(s) changed since the last extraction", len(data))
+	}
+
 	switch *outputType {
 	case "json":
 		if err = writeToFile("data.json", data, json.Marshal); err != nil {
@@ -60,9 +117,21 @@ func main() {
 			log.Printf("Error writing XML file: %v", err)
 		}
 	case "files":
-		if err = exportToFiles(data, *templateFile); err != nil {
+		if err = exportToFiles(data, config, *templateDir); err != nil {
 			log.Printf("Error writing files: %v", err)
 		}
+	case "migrations":
+		if err = generateMigrations(data, "migrations"); err != nil {
+			log.Printf("Error writing migrations: %v", err)
+		}
+	case "migrations-diff":
+		if err = generateMigrationsDiff(data, "migrations"); err != nil {
+			log.Printf("Error writing migration diff: %v", err)
+		}
+	case "graph":
+		if err = generateDependencyGraph(data, "graph"); err != nil {
+			log.Printf("Error writing dependency graph: %v", err)
+		}
 	case "debug":
 		log.Printf("Data: %v", data)
 	default:
@@ -72,26 +141,36 @@ func main() {
 
 // filterData filters the TableInfo data based on include and exclude lists
 func filterData(data []TableInfo, includeTables, excludeTables []string) []TableInfo {
-	includes := make(map[string]bool)
+	keep := newTableFilter(includeTables, excludeTables)
+
+	var filteredData []TableInfo
+	for _, table := range data {
+		if keep(table) {
+			filteredData = append(filteredData, table)
+		}
+	}
+	return filteredData
+}
+
+// newTableFilter builds a predicate implementing the include/exclude list
+// semantics used by filterData, so the streaming "files" path in main() can
+// apply the same rule per object instead of on a collected batch.
+func newTableFilter(includeTables, excludeTables []string) func(TableInfo) bool {
+	includes := make(map[string]bool, len(includeTables))
 	for _, table := range includeTables {
 		includes[table] = true
 	}
-	excludes := make(map[string]bool)
+	excludes := make(map[string]bool, len(excludeTables))
 	for _, table := range excludeTables {
 		excludes[table] = true
 	}
 
-	var filteredData []TableInfo
-	for _, table := range data {
+	return func(table TableInfo) bool {
 		if len(includes) > 0 && !includes[table.TableName] {
-			continue
-		}
-		if excludes[table.TableName] {
-			continue
+			return false
 		}
-		filteredData = append(filteredData, table)
+		return !excludes[table.TableName]
 	}
-	return filteredData
 }
 
 // parseCachedData parses cached data from json file to TableInfo slice
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// generateMigrations walks data and emits versioned migration file pairs
+// compatible with golang-migrate/goose layouts (NNNN_create_<table>.up.sql /
+// .down.sql) into dir, ordering tables/views so that objects referenced by
+// others (per Dependencies) are created first.
+func generateMigrations(data []TableInfo, dir string) error {
+	createDirectory(dir)
+
+	ordered, cyclic := topoSortTables(data)
+	for _, name := range cyclic {
+		log.Printf("Warning: cyclic dependency detected involving %s, migration order for it is not guaranteed", name)
+	}
+
+	version := nextMigrationVersion(dir)
+	for _, info := range ordered {
+		name := fmt.Sprintf("%04d_create_%s", version, cleanFn(strings.ToLower(info.TableName)))
+		up, down := buildMigrationSQL(info)
+
+		if err := os.WriteFile(filepath.Join(dir, name+".up.sql"), []byte(up), 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".down.sql"), []byte(down), 0644); err != nil {
+			return err
+		}
+		version++
+	}
+	return nil
+}
+
+// generateMigrationsDiff compares the freshly queried data against the
+// previously cached data.json (parseCachedData) and emits a single migration
+// pair capturing the column-level schema drift between the two.
+func generateMigrationsDiff(data []TableInfo, dir string) error {
+	cached, err := parseCachedData()
+	if err != nil {
+		return fmt.Errorf("loading cached data.json for diff: %w", err)
+	}
+
+	cachedByName := make(map[string]TableInfo, len(cached))
+	for _, t := range cached {
+		cachedByName[t.TableName] = t
+	}
+
+	createDirectory(dir)
+
+	var upStatements, downStatements []string
+	for _, current := range data {
+		old, existed := cachedByName[current.TableName]
+		if !existed {
+			continue // brand-new tables are handled by the plain "migrations" mode
+		}
+
+		added, dropped := diffColumns(old.Columns, current.Columns)
+		for _, col := range added {
+			upStatements = append(upStatements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", current.TableName, columnDDL(col)))
+			downStatements = append(downStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", current.TableName, col.Name))
+		}
+		for _, col := range dropped {
+			upStatements = append(upStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", current.TableName, col.Name))
+			downStatements = append(downStatements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", current.TableName, columnDDL(col)))
+		}
+	}
+
+	if len(upStatements) == 0 {
+		log.Println("No schema drift detected against data.json, skipping migrations-diff output")
+		return nil
+	}
+
+	name := fmt.Sprintf("%04d_schema_drift", nextMigrationVersion(dir))
+	up := strings.Join(upStatements, "\n") + "\n"
+	down := strings.Join(downStatements, "\n") + "\n"
+
+	if err := os.WriteFile(filepath.Join(dir, name+".up.sql"), []byte(up), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".down.sql"), []byte(down), 0644)
+}
+
+// diffColumns compares two column sets by name and reports which columns
+// were added and which were dropped going from old to current.
+func diffColumns(old, current []Column) (added, dropped []Column) {
+	oldByName := make(map[string]Column, len(old))
+	for _, c := range old {
+		oldByName[c.Name] = c
+	}
+	currentByName := make(map[string]Column, len(current))
+	for _, c := range current {
+		currentByName[c.Name] = c
+	}
+
+	for _, c := range current {
+		if _, ok := oldByName[c.Name]; !ok {
+			added = append(added, c)
+		}
+	}
+	for _, c := range old {
+		if _, ok := currentByName[c.Name]; !ok {
+			dropped = append(dropped, c)
+		}
+	}
+	return added, dropped
+}
+
+// nextMigrationVersion scans dir for existing NNNN_*.sql files and returns
+// the next free version number, so repeated runs keep appending migrations
+// instead of overwriting earlier ones.
+func nextMigrationVersion(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 1
+	}
+
+	max := 0
+	for _, e := range entries {
+		var v int
+		if _, err := fmt.Sscanf(e.Name(), "%04d_", &v); err == nil && v > max {
+			max = v
+		}
+	}
+	return max + 1
+}
+
+// buildMigrationSQL synthesizes the up/down SQL for a single table or view.
+func buildMigrationSQL(info TableInfo) (up, down string) {
+	if strings.EqualFold(info.Type, "View") {
+		up = fmt.Sprintf("CREATE OR REPLACE VIEW %s AS\n%s;\n", info.TableName, strings.TrimSpace(info.Definition))
+		down = fmt.Sprintf("DROP VIEW IF EXISTS %s;\n", info.TableName)
+		return up, down
+	}
+
+	cols := make([]string, 0, len(info.Columns))
+	for _, col := range info.Columns {
+		cols = append(cols, "    "+columnDDL(col))
+	}
+	up = fmt.Sprintf("CREATE TABLE %s (\n%s\n);\n", info.TableName, strings.Join(cols, ",\n"))
+	down = fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", info.TableName)
+	return up, down
+}
+
+// columnDDL renders a single column definition for CREATE TABLE/ALTER TABLE
+// statements, respecting length/precision/scale, nullability and identity.
+func columnDDL(col Column) string {
+	ddl := col.Name + " " + sqlColumnType(col)
+	if col.Is_Identity {
+		ddl += " PRIMARY KEY"
+	}
+	if !col.Is_Nullable && !col.Is_Identity {
+		ddl += " NOT NULL"
+	}
+	if col.Collation_Name != "" {
+		ddl += " COLLATE " + col.Collation_Name
+	}
+	return ddl
+}
+
+// sqlColumnType renders a column's type name together with its
+// length/precision/scale, when set.
+func sqlColumnType(col Column) string {
+	switch {
+	case col.Precision > 0 && col.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", col.Type_Name, col.Precision, col.Scale)
+	case col.Max_Length > 0:
+		return fmt.Sprintf("%s(%d)", col.Type_Name, col.Max_Length)
+	default:
+		return col.Type_Name
+	}
+}
+
+// topoSortTables orders data so that every table/view referenced via
+// Dependencies comes before the tables/views that depend on it (Kahn's
+// algorithm). Objects that cannot be ordered (those in a dependency cycle and
+// those that depend on one) are still returned, appended in input order, and
+// reported back via the second return value so callers can warn about them.
+func topoSortTables(data []TableInfo) (ordered []TableInfo, cyclic []string) {
+	byName := make(map[string]TableInfo, len(data))
+	inDegree := make(map[string]int, len(data))
+	dependents := make(map[string][]string)
+
+	for _, t := range data {
+		byName[t.TableName] = t
+		if _, ok := inDegree[t.TableName]; !ok {
+			inDegree[t.TableName] = 0
+		}
+	}
+	for _, t := range data {
+		for _, dep := range t.Dependencies {
+			if _, ok := byName[dep.ReferencedTable]; !ok {
+				continue // dependency outside this extraction, ignore for ordering
+			}
+			inDegree[t.TableName]++
+			dependents[dep.ReferencedTable] = append(dependents[dep.ReferencedTable], t.TableName)
+		}
+	}
+
+	var queue []string
+	for _, t := range data {
+		if inDegree[t.TableName] == 0 {
+			queue = append(queue, t.TableName)
+		}
+	}
+	sort.Strings(queue)
+
+	var order []string
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		order = append(order, name)
+
+		var next []string
+		for _, dependent := range dependents[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				next = append(next, dependent)
+			}
+		}
+		sort.Strings(next)
+		queue = append(queue, next...)
+	}
+
+	if len(order) != len(data) {
+		seen := make(map[string]bool, len(order))
+		for _, n := range order {
+			seen[n] = true
+		}
+		for _, t := range data {
+			if !seen[t.TableName] {
+				cyclic = append(cyclic, t.TableName)
+				order = append(order, t.TableName)
+			}
+		}
+	}
+
+	ordered = make([]TableInfo, 0, len(order))
+	for _, name := range order {
+		ordered = append(ordered, byName[name])
+	}
+	return ordered, cyclic
+}
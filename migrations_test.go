@@ -0,0 +1,126 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func tableInfo(name string, deps ...string) TableInfo {
+	t := TableInfo{TableName: name, Type: "Table"}
+	for _, d := range deps {
+		t.Dependencies = append(t.Dependencies, Dependency{ReferencedTable: d})
+	}
+	return t
+}
+
+func TestTopoSortTablesOrdersDependenciesFirst(t *testing.T) {
+	data := []TableInfo{
+		tableInfo("orders", "customers"),
+		tableInfo("customers"),
+		tableInfo("order_items", "orders"),
+	}
+
+	ordered, cyclic := topoSortTables(data)
+	if len(cyclic) != 0 {
+		t.Fatalf("expected no cyclic tables, got %v", cyclic)
+	}
+
+	pos := make(map[string]int, len(ordered))
+	for i, info := range ordered {
+		pos[info.TableName] = i
+	}
+	if pos["customers"] >= pos["orders"] || pos["orders"] >= pos["order_items"] {
+		t.Fatalf("expected customers, orders, order_items in that order; got %v", ordered)
+	}
+}
+
+func TestTopoSortTablesReportsCycle(t *testing.T) {
+	data := []TableInfo{
+		tableInfo("a", "b"),
+		tableInfo("b", "a"),
+	}
+
+	ordered, cyclic := topoSortTables(data)
+	if len(ordered) != len(data) {
+		t.Fatalf("expected every table to still be returned, got %v", ordered)
+	}
+
+	sort.Strings(cyclic)
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(cyclic, want) {
+		t.Fatalf("expected cyclic tables %v, got %v", want, cyclic)
+	}
+}
+
+func TestTopoSortTablesIgnoresDependenciesOutsideTheExtraction(t *testing.T) {
+	data := []TableInfo{
+		tableInfo("orders", "customers"), // customers isn't in data
+	}
+
+	ordered, cyclic := topoSortTables(data)
+	if len(cyclic) != 0 {
+		t.Fatalf("expected no cyclic tables, got %v", cyclic)
+	}
+	if len(ordered) != 1 || ordered[0].TableName != "orders" {
+		t.Fatalf("expected [orders], got %v", ordered)
+	}
+}
+
+func TestDiffColumnsAddedAndDropped(t *testing.T) {
+	old := []Column{{Name: "id"}, {Name: "legacy_flag"}}
+	current := []Column{{Name: "id"}, {Name: "email"}}
+
+	added, dropped := diffColumns(old, current)
+
+	if len(added) != 1 || added[0].Name != "email" {
+		t.Fatalf("expected added=[email], got %v", added)
+	}
+	if len(dropped) != 1 || dropped[0].Name != "legacy_flag" {
+		t.Fatalf("expected dropped=[legacy_flag], got %v", dropped)
+	}
+}
+
+func TestDiffColumnsNoChange(t *testing.T) {
+	cols := []Column{{Name: "id"}, {Name: "email"}}
+
+	added, dropped := diffColumns(cols, cols)
+	if len(added) != 0 || len(dropped) != 0 {
+		t.Fatalf("expected no diff, got added=%v dropped=%v", added, dropped)
+	}
+}
+
+func TestBuildMigrationSQLTable(t *testing.T) {
+	info := TableInfo{
+		TableName: "users",
+		Type:      "Table",
+		Columns: []Column{
+			{Name: "id", Type_Name: "int", Is_Identity: true},
+			{Name: "email", Type_Name: "varchar", Max_Length: 255, Is_Nullable: false},
+		},
+	}
+
+	up, down := buildMigrationSQL(info)
+	if want := "CREATE TABLE users ("; !strings.Contains(up, want) {
+		t.Fatalf("expected up SQL to contain %q, got %q", want, up)
+	}
+	if want := "email varchar(255) NOT NULL"; !strings.Contains(up, want) {
+		t.Fatalf("expected up SQL to contain %q, got %q", want, up)
+	}
+	if want := "DROP TABLE IF EXISTS users;\n"; down != want {
+		t.Fatalf("expected down SQL %q, got %q", want, down)
+	}
+}
+
+func TestBuildMigrationSQLView(t *testing.T) {
+	info := TableInfo{TableName: "active_users", Type: "View", Definition: "SELECT * FROM users WHERE active = 1"}
+
+	up, down := buildMigrationSQL(info)
+	if want := "CREATE OR REPLACE VIEW active_users AS\nSELECT * FROM users WHERE active = 1;\n"; up != want {
+		t.Fatalf("expected up SQL %q, got %q", want, up)
+	}
+	if want := "DROP VIEW IF EXISTS active_users;\n"; down != want {
+		t.Fatalf("expected down SQL %q, got %q", want, down)
+	}
+}
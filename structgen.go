@@ -0,0 +1,145 @@
+package main
+
+import "strings"
+
+// structField is one rendered field of a generated Go struct: its Go
+// identifier, resolved Go type (pointer- or sql.Null-wrapped when the
+// column is nullable, per StructOptions.NullableStyle) and the combined
+// struct tag string (without surrounding backticks).
+type structField struct {
+	Name   string
+	GoType string
+	Tags   string
+}
+
+// defaultStructTags is used when Config.StructOptions.Tags is empty, to
+// keep behavior identical to the plain `json:"..."` structs generated
+// before struct tags became configurable.
+var defaultStructTags = []string{"json"}
+
+// singularize converts a plausibly-plural English identifier to its
+// singular form (categories -> category, boxes -> box, users -> user),
+// following the same handful of suffix rules ORM reverse-generators like
+// xorm and beego's g_mvcgen.go use to turn a table name into a struct name.
+// Identifiers it doesn't recognize as plural are returned unchanged.
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > len("ies"):
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "ses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return s[:len(s)-1]
+	default:
+		return s
+	}
+}
+
+// buildStructFields turns a table's Columns into structFields ready for the
+// struct.go.tmpl template, applying the nullable-wrapping and tag-selection
+// rules from opts.
+func buildStructFields(columns []Column, dialect Dialect, opts StructOptions) []structField {
+	fields := make([]structField, 0, len(columns))
+	for _, col := range columns {
+		name := col.Name
+		if opts.PascalCaseNames {
+			name = pascalCase(name)
+		}
+		fields = append(fields, structField{
+			Name:   name,
+			GoType: columnGoType(col, dialect, opts),
+			Tags:   buildFieldTags(col, opts),
+		})
+	}
+	return fields
+}
+
+// columnGoType resolves the Go type for a column, wrapping nullable columns
+// as a pointer or a database/sql.NullXxx type according to opts.NullableStyle.
+func columnGoType(col Column, dialect Dialect, opts StructOptions) string {
+	base := dialect.MapType(col.Type_Name)
+	if !col.Is_Nullable {
+		return base
+	}
+
+	if opts.NullableStyle == "sql.Null" {
+		if wrapped, ok := sqlNullType(base); ok {
+			return wrapped
+		}
+	}
+	return "*" + base
+}
+
+// sqlNullType maps a base Go type to its database/sql.NullXxx equivalent,
+// when one exists.
+func sqlNullType(base string) (string, bool) {
+	switch base {
+	case "string":
+		return "sql.NullString", true
+	case "int", "int8", "int16", "int32", "int64":
+		return "sql.NullInt64", true
+	case "float32", "float64":
+		return "sql.NullFloat64", true
+	case "bool":
+		return "sql.NullBool", true
+	case "time.Time":
+		return "sql.NullTime", true
+	default:
+		return "", false
+	}
+}
+
+// buildFieldTags renders the combined struct tag string for a column,
+// honoring the tag kinds selected via opts.Tags (defaulting to json-only).
+func buildFieldTags(col Column, opts StructOptions) string {
+	tags := opts.Tags
+	if len(tags) == 0 {
+		tags = defaultStructTags
+	}
+
+	var parts []string
+	for _, tag := range tags {
+		switch tag {
+		case "gorm":
+			gormTag := "column:" + col.Name
+			if col.Is_Identity {
+				gormTag += ";primaryKey;autoIncrement"
+			}
+			parts = append(parts, `gorm:"`+gormTag+`"`)
+		case "xorm":
+			xormTag := "'" + col.Name + "'"
+			if col.Is_Identity {
+				xormTag += " pk autoincr"
+			}
+			parts = append(parts, `xorm:"`+xormTag+`"`)
+		case "db":
+			parts = append(parts, `db:"`+col.Name+`"`)
+		case "json":
+			parts = append(parts, `json:"`+col.Name+`"`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// structImports returns the import paths required by the generated struct
+// (e.g. "time" or "database/sql" for sql.NullXxx/time.Time fields).
+func structImports(fields []structField) []string {
+	var needsTime, needsSQL bool
+	for _, f := range fields {
+		if strings.Contains(f.GoType, "time.Time") {
+			needsTime = true
+		}
+		if strings.HasPrefix(strings.TrimPrefix(f.GoType, "*"), "sql.Null") {
+			needsSQL = true
+		}
+	}
+
+	var imports []string
+	if needsSQL {
+		imports = append(imports, "database/sql")
+	}
+	if needsTime {
+		imports = append(imports, "time")
+	}
+	return imports
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColumnGoTypePointerStyle(t *testing.T) {
+	dialect := mysqlDialect{}
+	opts := StructOptions{}
+
+	col := Column{Type_Name: "int", Is_Nullable: false}
+	if got := columnGoType(col, dialect, opts); got != "int" {
+		t.Fatalf("expected int, got %s", got)
+	}
+
+	col.Is_Nullable = true
+	if got := columnGoType(col, dialect, opts); got != "*int" {
+		t.Fatalf("expected *int for a nullable column with the default pointer style, got %s", got)
+	}
+}
+
+func TestColumnGoTypeSQLNullStyle(t *testing.T) {
+	dialect := mysqlDialect{}
+	opts := StructOptions{NullableStyle: "sql.Null"}
+
+	col := Column{Type_Name: "varchar", Is_Nullable: true}
+	if got := columnGoType(col, dialect, opts); got != "sql.NullString" {
+		t.Fatalf("expected sql.NullString, got %s", got)
+	}
+
+	// Unrecognized types map to interface{}, which has no sql.NullXxx
+	// equivalent, so they fall back to a pointer.
+	col = Column{Type_Name: "enum", Is_Nullable: true}
+	if got := columnGoType(col, dialect, opts); got != "*interface{}" {
+		t.Fatalf("expected *interface{} fallback for a type with no sql.Null equivalent, got %s", got)
+	}
+}
+
+func TestColumnGoTypeMySQLTinyintOne(t *testing.T) {
+	dialect := mysqlDialect{}
+	opts := StructOptions{}
+
+	col := Column{Type_Name: "tinyint(1)"}
+	if got := columnGoType(col, dialect, opts); got != "bool" {
+		t.Fatalf("expected tinyint(1) to map to bool, got %s", got)
+	}
+
+	col = Column{Type_Name: "tinyint"}
+	if got := columnGoType(col, dialect, opts); got != "int8" {
+		t.Fatalf("expected plain tinyint to map to int8, got %s", got)
+	}
+}
+
+func TestBuildFieldTagsGorm(t *testing.T) {
+	opts := StructOptions{Tags: []string{"gorm"}}
+
+	tags := buildFieldTags(Column{Name: "id", Is_Identity: true}, opts)
+	if want := `gorm:"column:id;primaryKey;autoIncrement"`; tags != want {
+		t.Fatalf("expected %q, got %q", want, tags)
+	}
+
+	tags = buildFieldTags(Column{Name: "email"}, opts)
+	if want := `gorm:"column:email"`; tags != want {
+		t.Fatalf("expected %q, got %q", want, tags)
+	}
+}
+
+func TestBuildFieldTagsDefaultsToJSON(t *testing.T) {
+	tags := buildFieldTags(Column{Name: "email"}, StructOptions{})
+	if want := `json:"email"`; tags != want {
+		t.Fatalf("expected %q, got %q", want, tags)
+	}
+}
+
+func TestBuildStructFieldsPascalCaseNames(t *testing.T) {
+	columns := []Column{{Name: "user_id", Type_Name: "int"}}
+	fields := buildStructFields(columns, mysqlDialect{}, StructOptions{PascalCaseNames: true})
+
+	if len(fields) != 1 || fields[0].Name != "UserId" {
+		t.Fatalf("expected a single PascalCase field UserId, got %+v", fields)
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	cases := map[string]string{
+		"categories": "category",
+		"boxes":      "box",
+		"users":      "user",
+		"addresses":  "address",
+		"wishes":     "wish",
+		"watches":    "watch",
+		"data":       "data",
+	}
+	for in, want := range cases {
+		if got := singularize(in); got != want {
+			t.Errorf("singularize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerateGoStructSingularizesStructNameOnly(t *testing.T) {
+	templates, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := TableInfo{TableName: "users", Columns: []Column{{Name: "id", Type_Name: "int"}}}
+	opts := StructOptions{Singularize: true, PascalCaseNames: true}
+
+	out, err := generateGoStruct(view, mysqlDialect{}, opts, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "type User struct") {
+		t.Fatalf("expected singularized+PascalCase struct name User, got:\n%s", out)
+	}
+}
+
+func TestStructImportsDeduced(t *testing.T) {
+	fields := []structField{
+		{GoType: "int"},
+		{GoType: "*time.Time"},
+		{GoType: "sql.NullString"},
+	}
+
+	imports := structImports(fields)
+	if len(imports) != 2 {
+		t.Fatalf("expected database/sql and time to be required, got %v", imports)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// defaultTemplatesFS embeds the default set of output templates shipped with
+// the binary, used when no -template directory is supplied.
+//
+//go:embed templates/*.tmpl
+var defaultTemplatesFS embed.FS
+
+// TemplateSet is the parsed collection of templates used to render
+// generated artifacts (info.md, table.md, struct.go, view.sql). All three
+// output kinds are rendered with TableInfo (or a thin wrapper around it) as
+// the template data.
+type TemplateSet struct {
+	tmpl *template.Template
+}
+
+// templateFuncs are available to every template in the set.
+var templateFuncs = template.FuncMap{
+	"toLower":    strings.ToLower,
+	"pascalCase": pascalCase,
+}
+
+// loadTemplates builds a TemplateSet from the embedded defaults, optionally
+// overridden by user-supplied *.tmpl files in templateDir. Passing an empty
+// templateDir yields the built-in templates unmodified.
+func loadTemplates(templateDir string) (*TemplateSet, error) {
+	tmpl, err := template.New("templates").Funcs(templateFuncs).ParseFS(defaultTemplatesFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	if templateDir != "" {
+		tmpl, err = tmpl.ParseGlob(filepath.Join(templateDir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TemplateSet{tmpl: tmpl}, nil
+}
+
+// render executes the named template against data and returns the result.
+func (ts *TemplateSet) render(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := ts.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// pascalCase converts a snake_case or kebab-case identifier to PascalCase,
+// used by templates that generate ORM-friendly Go identifiers.
+func pascalCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' '
+	})
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The expected strings below reproduce exactly what the pre-template
+// string-concatenation versions of generateInfoFile/generateTableInfoFile/
+// generateGoStruct produced (see git history prior to the chunk0-2 template
+// refactor), so a future template edit that silently changes whitespace or
+// casing fails here instead of only showing up in a diff of generated files.
+
+func TestLoadTemplatesDefaultInfoFileMatchesOldOutput(t *testing.T) {
+	templates, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := TableInfo{
+		Database:   "MyDB",
+		Schema:     "dbo",
+		TableName:  "MyView",
+		Definition: "Commit;alice;2024-01-02;did a thing\nSELECT 1",
+		Columns:    []Column{{Name: "id", Type_Name: "int"}},
+		Dependencies: []Dependency{
+			{ReferencedDB: "MyDB", ReferencedSchema: "dbo", ReferencedTable: "OtherTable"},
+		},
+	}
+
+	got, err := generateInfoFile(view, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Infodatei zum View [mydb.dbo.MyView](../../mydb/dbo/MyView.sql)\n\n" +
+		"\n\n" +
+		"## Tabellenstruktur\n\n" +
+		"Name|Type|Length|Precision|Scale|Collation|Nullable|Identity\n--|--|--|--|--|--|--|--\n" +
+		"id|int|0|0|0||0|0\n" +
+		"\n\n## Änderungen\n\nBenutzer|Datum|Kommentar\n--|--|--\n" +
+		"alice|2024-01-02|did a thing\n" +
+		"\n## Abhängigkeiten\n\nDB|Schema|Tabelle/View\n--|--|--\n" +
+		"mydb|dbo|[othertable](../../mydb/dbo/OtherTable.info.md)\n" +
+		"\n\n"
+
+	if got != want {
+		t.Fatalf("generateInfoFile output mismatch:\n--- got ---\n%q\n--- want ---\n%q", got, want)
+	}
+}
+
+func TestLoadTemplatesDefaultTableFileMatchesOldOutput(t *testing.T) {
+	templates, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := TableInfo{
+		Database:   "MyDB",
+		Schema:     "dbo",
+		TableName:  "MyTable",
+		Definition: "",
+		Columns:    []Column{{Name: "id", Type_Name: "int"}},
+	}
+
+	got, err := generateTableInfoFile(view, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Infodatei zur Tabelle mydb.dbo.MyTable\n\n" +
+		"## Tabellenstruktur\n\n" +
+		"Name|Type|Length|Precision|Scale|Collation|Nullable|Identity\n--|--|--|--|--|--|--|--\n" +
+		"id|int|0|0|0||0|0\n"
+
+	if got != want {
+		t.Fatalf("generateTableInfoFile output mismatch:\n--- got ---\n%q\n--- want ---\n%q", got, want)
+	}
+}
+
+func TestLoadTemplatesDefaultStructFileMatchesOldOutput(t *testing.T) {
+	templates, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := TableInfo{
+		TableName: "MyTable",
+		Columns:   []Column{{Name: "id", Type_Name: "int"}},
+	}
+
+	got, err := generateGoStruct(view, mysqlDialect{}, StructOptions{}, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "package main\n\n" +
+		"// MyTable represents a database table/view structure\n" +
+		"type MyTable struct {\n" +
+		"\tid int `json:\"id\"`\n" +
+		"}\n"
+
+	if got != want {
+		t.Fatalf("generateGoStruct output mismatch:\n--- got ---\n%q\n--- want ---\n%q", got, want)
+	}
+}
+
+// TestLoadTemplatesOverrideDirOverridesOnlyOneTemplate verifies that a
+// user-supplied -template directory replaces a single named template while
+// every other default (embedded) template keeps rendering as before.
+func TestLoadTemplatesOverrideDirOverridesOnlyOneTemplate(t *testing.T) {
+	dir := t.TempDir()
+	overridePath := filepath.Join(dir, "view.sql.tmpl")
+	if err := os.WriteFile(overridePath, []byte("{{define \"view.sql.tmpl\"}}-- overridden\n{{.Definition}}{{end}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := loadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := TableInfo{TableName: "MyTable", Definition: "SELECT 1"}
+
+	sql, err := templates.render("view.sql.tmpl", view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-- overridden\nSELECT 1"; sql != want {
+		t.Fatalf("expected the overridden view.sql.tmpl to render %q, got %q", want, sql)
+	}
+
+	table, err := generateTableInfoFile(TableInfo{TableName: "MyTable", Database: "db", Schema: "dbo"}, templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Infodatei zur Tabelle db.dbo.MyTable\n\n## Tabellenstruktur\n\nName|Type|Length|Precision|Scale|Collation|Nullable|Identity\n--|--|--|--|--|--|--|--\n"; table != want {
+		t.Fatalf("expected table.md.tmpl to still use the default template, got %q", table)
+	}
+}